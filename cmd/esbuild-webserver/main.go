@@ -8,8 +8,13 @@ import (
 )
 
 var args struct {
-	Endpoints []string `arg:"-e,--endpoint,separate,required"`
-	Listen    string   `arg:"-l,--listen" default:"127.0.0.1:8080"`
+	Endpoints        []string `arg:"-e,--endpoint,separate,required"`
+	Listen           string   `arg:"-l,--listen" default:"127.0.0.1:8080"`
+	ListenHTTP       string   `arg:"--listen-http" help:"also listen on this address and redirect plain HTTP to --listen; requires --cert/--key or --autocert"`
+	Cert             string   `arg:"--cert" help:"TLS certificate file, for serving HTTPS on --listen"`
+	Key              string   `arg:"--key" help:"TLS key file, for serving HTTPS on --listen"`
+	Autocert         []string `arg:"--autocert,separate" help:"domain to obtain a Let's Encrypt certificate for via ACME; may be given multiple times"`
+	AutocertCacheDir string   `arg:"--autocert-cache-dir" default:"autocert-cache" help:"directory to cache ACME certificates in"`
 }
 
 func main() {
@@ -20,6 +25,17 @@ func main() {
 		logrus.Fatalf("unable to create webserver: %v", err)
 	}
 
+	var opts []webserver.StartOption
+	switch {
+	case len(args.Autocert) > 0:
+		opts = append(opts, webserver.WithAutocert(args.Autocert, args.AutocertCacheDir))
+	case args.Cert != "" && args.Key != "":
+		opts = append(opts, webserver.WithTLS(args.Cert, args.Key))
+	}
+	if args.ListenHTTP != "" {
+		opts = append(opts, webserver.WithHTTPRedirect(args.ListenHTTP))
+	}
+
 	fmt.Printf("listening on %v\n", args.Listen)
-	logrus.Fatal(s.Start(args.Listen))
+	logrus.Fatal(s.Start(args.Listen, opts...))
 }
@@ -0,0 +1,48 @@
+package webserver
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileETagEntry caches the computed ETag for a file alongside the mtime it
+// was computed from, so a changed mtime invalidates the entry.
+type fileETagEntry struct {
+	modTime time.Time
+	etag    string
+}
+
+var etagCache sync.Map // absolute path (string) -> fileETagEntry
+
+// etagFor returns a quoted, weak-free ETag for the file at absPath, reusing
+// a cached value when modTime matches what was last hashed. The ETag is the
+// FNV-64a hash of the file contents, which is cheap enough to recompute on
+// every mtime change but avoids re-hashing unchanged, content-addressed
+// esbuild output on every request.
+func etagFor(absPath string, modTime time.Time) (string, error) {
+	if cached, ok := etagCache.Load(absPath); ok {
+		entry := cached.(fileETagEntry)
+		if entry.modTime.Equal(modTime) {
+			return entry.etag, nil
+		}
+	}
+
+	file, err := os.Open(absPath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := fnv.New64a()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+
+	etag := fmt.Sprintf(`"%x"`, h.Sum64())
+	etagCache.Store(absPath, fileETagEntry{modTime: modTime, etag: etag})
+	return etag, nil
+}
@@ -0,0 +1,293 @@
+package webserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/sirupsen/logrus"
+)
+
+// buildConfig is the on-disk shape of the JSON file referenced by a
+// `build=<path>` endpoint (e.g. `-e /:build=esbuild.json`). It covers the
+// subset of api.BuildOptions needed to drive a watch build; anything more
+// exotic should go through esbuild's own CLI/config instead.
+type buildConfig struct {
+	EntryPoints []string          `json:"entryPoints"`
+	Outdir      string            `json:"outdir"`
+	Bundle      bool              `json:"bundle"`
+	Minify      bool              `json:"minify"`
+	Sourcemap   bool              `json:"sourcemap"`
+	Splitting   bool              `json:"splitting"`
+	Format      string            `json:"format"`
+	Platform    string            `json:"platform"`
+	Define      map[string]string `json:"define"`
+}
+
+func loadBuildConfig(configPath string) (*buildConfig, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read build config %s: %w", configPath, err)
+	}
+	cfg := &buildConfig{Format: "esm", Outdir: "out"}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("unable to parse build config %s: %w", configPath, err)
+	}
+	return cfg, nil
+}
+
+func (c *buildConfig) toBuildOptions() api.BuildOptions {
+	format := api.FormatDefault
+	switch c.Format {
+	case "iife":
+		format = api.FormatIIFE
+	case "cjs":
+		format = api.FormatCommonJS
+	case "esm":
+		format = api.FormatESModule
+	}
+
+	platform := api.PlatformBrowser
+	if c.Platform == "node" {
+		platform = api.PlatformNode
+	}
+
+	sourcemap := api.SourceMapNone
+	if c.Sourcemap {
+		sourcemap = api.SourceMapInline
+	}
+
+	define := make(map[string]string, len(c.Define))
+	for k, v := range c.Define {
+		define[k] = v
+	}
+
+	return api.BuildOptions{
+		EntryPoints:       c.EntryPoints,
+		Outdir:            c.Outdir,
+		Bundle:            c.Bundle,
+		MinifyWhitespace:  c.Minify,
+		MinifyIdentifiers: c.Minify,
+		MinifySyntax:      c.Minify,
+		Splitting:         c.Splitting,
+		Format:            format,
+		Platform:          platform,
+		Sourcemap:         sourcemap,
+		Define:            define,
+		Write:             false,
+	}
+}
+
+// changeHub fans out esbuild rebuild notifications to any number of SSE
+// subscribers.
+type changeHub struct {
+	mu   sync.Mutex
+	subs map[chan string]struct{}
+}
+
+func newChangeHub() *changeHub {
+	return &changeHub{subs: map[chan string]struct{}{}}
+}
+
+func (h *changeHub) subscribe() chan string {
+	ch := make(chan string, 1)
+	h.mu.Lock()
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+	return ch
+}
+
+func (h *changeHub) unsubscribe(ch chan string) {
+	h.mu.Lock()
+	delete(h.subs, ch)
+	h.mu.Unlock()
+	close(ch)
+}
+
+func (h *changeHub) broadcast(event string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// buildHandler serves the in-memory output of a watched esbuild build and,
+// at the request path /esbuild, an SSE stream that emits a "change" event
+// after every successful rebuild so served pages can live-reload. Both are
+// matched against the full request URI, mount point included, so they line
+// up with the output paths only when the handler is mounted at /.
+type buildHandler struct {
+	mu     sync.RWMutex
+	files  map[string]api.OutputFile
+	outdir string
+	hub    *changeHub
+	logger *logrus.Logger
+}
+
+func (s *Server) newBuildHandler(configPath string) (*buildHandler, error) {
+	cfg, err := loadBuildConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	outdir, err := filepath.Abs(cfg.Outdir)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve outdir %s: %w", cfg.Outdir, err)
+	}
+
+	handler := &buildHandler{
+		files:  map[string]api.OutputFile{},
+		outdir: outdir,
+		hub:    newChangeHub(),
+		logger: s.logger,
+	}
+
+	options := cfg.toBuildOptions()
+	options.Plugins = append(options.Plugins, api.Plugin{
+		Name: "esbuild-webserver-live-reload",
+		Setup: func(build api.PluginBuild) {
+			build.OnEnd(func(result *api.BuildResult) (api.OnEndResult, error) {
+				if len(result.Errors) > 0 {
+					handler.logger.Debugf("esbuild rebuild failed: %d error(s), keeping last good output", len(result.Errors))
+					return api.OnEndResult{}, nil
+				}
+				handler.updateOutputs(result.OutputFiles)
+				handler.logger.Debugf("esbuild rebuild complete: %d output file(s)", len(result.OutputFiles))
+				handler.hub.broadcast("change")
+				return api.OnEndResult{}, nil
+			})
+		},
+	})
+
+	ctx, err := api.Context(options)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create esbuild context for %s: %w", configPath, err)
+	}
+	if err := ctx.Watch(api.WatchOptions{}); err != nil {
+		return nil, fmt.Errorf("unable to start esbuild watch for %s: %w", configPath, err)
+	}
+
+	return handler, nil
+}
+
+// updateOutputs keys each output file by its path relative to outdir (e.g.
+// "app.js" or "chunks/shared-AB12.js"), so requests are matched exactly
+// instead of by an ambiguous basename suffix scan across entry points/chunks.
+func (b *buildHandler) updateOutputs(outputFiles []api.OutputFile) {
+	files := make(map[string]api.OutputFile, len(outputFiles))
+	for _, f := range outputFiles {
+		rel, err := filepath.Rel(b.outdir, f.Path)
+		if err != nil {
+			rel = filepath.Base(f.Path)
+		}
+		files[filepath.ToSlash(rel)] = f
+	}
+
+	b.mu.Lock()
+	b.files = files
+	b.mu.Unlock()
+}
+
+func (b *buildHandler) lookup(uri string) (api.OutputFile, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	f, ok := b.files[uri]
+	return f, ok
+}
+
+func (b *buildHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	uri := strings.TrimPrefix(request.RequestURI, "/")
+	if uri == "esbuild" {
+		b.serveChangeStream(writer, request)
+		return
+	}
+	if uri == "" {
+		uri = "index.html"
+	}
+
+	file, ok := b.lookup(uri)
+	if !ok {
+		http.Error(writer, "404 - Not found", http.StatusNotFound)
+		return
+	}
+
+	contents := file.Contents
+	contentType := mime.TypeByExtension(path.Ext(uri))
+	if strings.HasPrefix(contentType, "text/html") {
+		contents = injectLiveReloadScript(contents)
+	}
+
+	writer.Header().Set("Content-Type", contentType)
+	_, _ = writer.Write(contents)
+}
+
+func (b *buildHandler) serveChangeStream(writer http.ResponseWriter, request *http.Request) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		http.Error(writer, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ch := b.hub.subscribe()
+	defer b.hub.unsubscribe(ch)
+
+	for {
+		select {
+		case event, open := <-ch:
+			if !open {
+				return
+			}
+			_, _ = fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event, event)
+			flusher.Flush()
+		case <-request.Context().Done():
+			return
+		}
+	}
+}
+
+var _ http.Handler = (*buildHandler)(nil)
+
+// liveReloadScript is injected into any served HTML response so the page
+// can subscribe to /esbuild and reload itself on the next "change" event.
+var liveReloadScript = []byte(`
+<script>
+(function() {
+	var source = new EventSource("/esbuild");
+	source.addEventListener("change", function() {
+		window.location.reload();
+	});
+})();
+</script>
+`)
+
+func injectLiveReloadScript(html []byte) []byte {
+	idx := bytes.LastIndex(html, []byte("</body>"))
+	if idx == -1 {
+		return append(html, liveReloadScript...)
+	}
+
+	out := make([]byte, 0, len(html)+len(liveReloadScript))
+	out = append(out, html[:idx]...)
+	out = append(out, liveReloadScript...)
+	out = append(out, html[idx:]...)
+	return out
+}
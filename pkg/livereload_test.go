@@ -0,0 +1,61 @@
+package webserver
+
+import (
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+)
+
+func TestBuildHandlerLookupByRelativePath(t *testing.T) {
+	handler := &buildHandler{outdir: "/build/out"}
+
+	handler.updateOutputs([]api.OutputFile{
+		{Path: "/build/out/app.js", Contents: []byte("app")},
+		{Path: "/build/out/chunks/shared-AB12.js", Contents: []byte("shared")},
+	})
+
+	f, ok := handler.lookup("app.js")
+	if !ok || string(f.Contents) != "app" {
+		t.Fatalf("lookup(app.js) = %q, %v, want \"app\", true", f.Contents, ok)
+	}
+
+	f, ok = handler.lookup("chunks/shared-AB12.js")
+	if !ok || string(f.Contents) != "shared" {
+		t.Fatalf("lookup(chunks/shared-AB12.js) = %q, %v, want \"shared\", true", f.Contents, ok)
+	}
+
+	if _, ok := handler.lookup("missing.js"); ok {
+		t.Fatal("lookup(missing.js) unexpectedly found a file")
+	}
+}
+
+func TestBuildHandlerLookupDisambiguatesSameBasenameInDifferentDirs(t *testing.T) {
+	handler := &buildHandler{outdir: "/build/out"}
+
+	handler.updateOutputs([]api.OutputFile{
+		{Path: "/build/out/a/index.js", Contents: []byte("a")},
+		{Path: "/build/out/b/index.js", Contents: []byte("b")},
+	})
+
+	f, ok := handler.lookup("a/index.js")
+	if !ok || string(f.Contents) != "a" {
+		t.Fatalf("lookup(a/index.js) = %q, %v, want \"a\", true", f.Contents, ok)
+	}
+
+	f, ok = handler.lookup("b/index.js")
+	if !ok || string(f.Contents) != "b" {
+		t.Fatalf("lookup(b/index.js) = %q, %v, want \"b\", true", f.Contents, ok)
+	}
+}
+
+func TestBuildHandlerUpdateOutputsReplacesPreviousFiles(t *testing.T) {
+	handler := &buildHandler{outdir: "/build/out"}
+
+	handler.updateOutputs([]api.OutputFile{{Path: "/build/out/app.js", Contents: []byte("v1")}})
+	handler.updateOutputs([]api.OutputFile{{Path: "/build/out/app.js", Contents: []byte("v2")}})
+
+	f, ok := handler.lookup("app.js")
+	if !ok || string(f.Contents) != "v2" {
+		t.Fatalf("lookup(app.js) = %q, %v, want \"v2\", true", f.Contents, ok)
+	}
+}
@@ -0,0 +1,129 @@
+package webserver
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Option configures a Server at construction time, in New.
+type Option func(*Server)
+
+// WithLogger overrides the default logger (a logrus.Logger at DebugLevel,
+// text formatter, writing to stderr).
+func WithLogger(logger *logrus.Logger) Option {
+	return func(s *Server) {
+		s.logger = logger
+	}
+}
+
+// WithJSONLogging switches the default logger's formatter to JSON, for
+// environments where logs are ingested by something other than a human
+// tail.
+func WithJSONLogging() Option {
+	return func(s *Server) {
+		s.logger.SetFormatter(&logrus.JSONFormatter{})
+	}
+}
+
+// WithLogOutput sets where the default logger writes to.
+func WithLogOutput(w io.Writer) Option {
+	return func(s *Server) {
+		s.logger.SetOutput(w)
+	}
+}
+
+// WithLogLevel sets the default logger's level.
+func WithLogLevel(level logrus.Level) Option {
+	return func(s *Server) {
+		s.logger.SetLevel(level)
+	}
+}
+
+// loggingResponseWriter wraps http.ResponseWriter to capture the status
+// code and byte count written, neither of which is otherwise observable
+// from middleware wrapping the handler.
+type loggingResponseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *loggingResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *loggingResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytes += n
+	return n, err
+}
+
+// Flush, Hijack, and CloseNotify forward to the wrapped ResponseWriter when
+// it supports them, so wrapping with loggingResponseWriter doesn't silently
+// strip streaming (SSE) or connection-hijacking (websocket) support from
+// handlers further down the chain.
+
+func (w *loggingResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *loggingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("underlying ResponseWriter does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+func (w *loggingResponseWriter) CloseNotify() <-chan bool {
+	notifier, ok := w.ResponseWriter.(http.CloseNotifier)
+	if !ok {
+		return make(chan bool)
+	}
+	return notifier.CloseNotify()
+}
+
+var (
+	_ http.Flusher       = (*loggingResponseWriter)(nil)
+	_ http.Hijacker      = (*loggingResponseWriter)(nil)
+	_ http.CloseNotifier = (*loggingResponseWriter)(nil)
+)
+
+// LoggingMiddleware logs each request as a structured logrus entry with
+// fields for method, path, status, bytes written, duration, remote addr,
+// and referrer.
+func (s Server) LoggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		lw := &loggingResponseWriter{ResponseWriter: w}
+
+		next.ServeHTTP(lw, r)
+
+		status := lw.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		s.logger.WithFields(logrus.Fields{
+			"method":   r.Method,
+			"path":     r.URL.Path,
+			"status":   status,
+			"bytes":    lw.bytes,
+			"duration": time.Since(start),
+			"remote":   r.RemoteAddr,
+			"referrer": r.Referer(),
+		}).Info("handled request")
+	})
+}
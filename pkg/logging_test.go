@@ -0,0 +1,61 @@
+package webserver
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type flushHijackRecorder struct {
+	*httptest.ResponseRecorder
+	flushed  bool
+	hijacked bool
+}
+
+func (f *flushHijackRecorder) Flush() {
+	f.flushed = true
+}
+
+func (f *flushHijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	f.hijacked = true
+	return nil, nil, nil
+}
+
+func TestLoggingResponseWriterForwardsFlushAndHijack(t *testing.T) {
+	underlying := &flushHijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	lw := &loggingResponseWriter{ResponseWriter: underlying}
+
+	flusher, ok := http.ResponseWriter(lw).(http.Flusher)
+	if !ok {
+		t.Fatal("loggingResponseWriter does not implement http.Flusher")
+	}
+	flusher.Flush()
+	if !underlying.flushed {
+		t.Fatal("Flush() was not forwarded to the underlying ResponseWriter")
+	}
+
+	hijacker, ok := http.ResponseWriter(lw).(http.Hijacker)
+	if !ok {
+		t.Fatal("loggingResponseWriter does not implement http.Hijacker")
+	}
+	if _, _, err := hijacker.Hijack(); err != nil {
+		t.Fatalf("Hijack() returned an error: %v", err)
+	}
+	if !underlying.hijacked {
+		t.Fatal("Hijack() was not forwarded to the underlying ResponseWriter")
+	}
+}
+
+func TestLoggingResponseWriterHijackUnsupported(t *testing.T) {
+	lw := &loggingResponseWriter{ResponseWriter: httptest.NewRecorder()}
+
+	hijacker, ok := http.ResponseWriter(lw).(http.Hijacker)
+	if !ok {
+		t.Fatal("loggingResponseWriter does not implement http.Hijacker")
+	}
+	if _, _, err := hijacker.Hijack(); err == nil {
+		t.Fatal("expected an error hijacking a ResponseWriter that doesn't support it")
+	}
+}
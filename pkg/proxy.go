@@ -0,0 +1,209 @@
+package webserver
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// proxyOptions are carried as query parameters on the `proxy=` endpoint
+// argument, e.g. `proxy=http://backend?strip=/api&preserveHost=true`.
+type proxyOptions struct {
+	stripPrefix  string
+	preserveHost bool
+}
+
+func parseProxyDest(raw string) (*url.URL, proxyOptions, error) {
+	destUrl, err := url.Parse(raw)
+	if err != nil {
+		return nil, proxyOptions{}, fmt.Errorf("unable to parse URL: %v", err)
+	}
+
+	query := destUrl.Query()
+	opts := proxyOptions{
+		stripPrefix:  query.Get("strip"),
+		preserveHost: query.Get("preserveHost") == "true",
+	}
+	destUrl.RawQuery = ""
+
+	return destUrl, opts, nil
+}
+
+// applyProxyRewrite rewrites req the way a reverse proxy in front of a
+// mounted backend should: the mount prefix stripped (via ?strip=), Host
+// either preserved or rewritten to the backend, and X-Forwarded-* set for
+// the backend to see the original request. It's shared between the
+// httputil.ReverseProxy director and the raw websocket path so both kinds
+// of request are rewritten identically.
+func applyProxyRewrite(req *http.Request, destUrl *url.URL, opts proxyOptions) {
+	originalHost := req.Host
+
+	// A ws(s):// destination is only meaningful for the upgrade itself
+	// (dialBackend uses destUrl.Scheme directly for that); an ordinary,
+	// non-upgrade request routed through httputil.ReverseProxy needs an
+	// http(s) scheme or http.Transport rejects it as unsupported.
+	req.URL.Scheme = transportScheme(destUrl.Scheme)
+	req.URL.Host = destUrl.Host
+	req.URL.Path = destUrl.Path + strings.TrimPrefix(req.URL.Path, opts.stripPrefix)
+
+	if !opts.preserveHost {
+		req.Host = destUrl.Host
+	}
+
+	if clientIP, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		if prior := req.Header.Get("X-Forwarded-For"); prior != "" {
+			clientIP = prior + ", " + clientIP
+		}
+		req.Header.Set("X-Forwarded-For", clientIP)
+	}
+	req.Header.Set("X-Forwarded-Host", originalHost)
+	req.Header.Set("X-Forwarded-Proto", requestScheme(req))
+}
+
+// reverseProxy builds a handler that forwards to dest, rewriting requests
+// via applyProxyRewrite. Websocket upgrade requests are proxied by
+// hijacking the connection, since httputil.ReverseProxy's Director/Transport
+// model cannot do a raw bidirectional byte copy on its own.
+func reverseProxy(dest string) (http.Handler, error) {
+	destUrl, opts, err := parseProxyDest(dest)
+	if err != nil {
+		return nil, err
+	}
+
+	director := func(req *http.Request) {
+		applyProxyRewrite(req, destUrl, opts)
+	}
+
+	transport := &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+		IdleConnTimeout:       90 * time.Second,
+	}
+
+	return &websocketAwareProxy{
+		httpProxy: &httputil.ReverseProxy{Director: director, Transport: transport},
+		destUrl:   destUrl,
+		opts:      opts,
+	}, nil
+}
+
+func requestScheme(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// transportScheme maps a ws(s) destination scheme to the http(s) equivalent
+// that http.Transport understands, leaving http/https untouched.
+func transportScheme(scheme string) string {
+	switch scheme {
+	case "ws":
+		return "http"
+	case "wss":
+		return "https"
+	default:
+		return scheme
+	}
+}
+
+// websocketAwareProxy delegates ordinary requests to an
+// httputil.ReverseProxy and hijacks the connection for websocket upgrades,
+// copying bytes bidirectionally between client and backend.
+type websocketAwareProxy struct {
+	httpProxy *httputil.ReverseProxy
+	destUrl   *url.URL
+	opts      proxyOptions
+}
+
+func (p *websocketAwareProxy) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if !isWebsocketUpgrade(request) {
+		p.httpProxy.ServeHTTP(writer, request)
+		return
+	}
+	p.proxyWebsocket(writer, request)
+}
+
+func isWebsocketUpgrade(request *http.Request) bool {
+	return strings.EqualFold(request.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(request.Header.Get("Connection")), "upgrade")
+}
+
+// isTLSScheme reports whether a destination scheme implies the backend
+// expects a TLS connection (https for the initial handshake, wss if given
+// directly as the proxy target).
+func isTLSScheme(scheme string) bool {
+	return scheme == "https" || scheme == "wss"
+}
+
+// backendAddr returns destUrl.Host with the scheme's default port applied
+// if the host doesn't already specify one, since net.Dial (unlike
+// http.Transport) doesn't default ports for us.
+func backendAddr(destUrl *url.URL) string {
+	if _, _, err := net.SplitHostPort(destUrl.Host); err == nil {
+		return destUrl.Host
+	}
+	port := "80"
+	if isTLSScheme(destUrl.Scheme) {
+		port = "443"
+	}
+	return net.JoinHostPort(destUrl.Host, port)
+}
+
+func (p *websocketAwareProxy) dialBackend() (net.Conn, error) {
+	addr := backendAddr(p.destUrl)
+	if isTLSScheme(p.destUrl.Scheme) {
+		return tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", addr, &tls.Config{ServerName: p.destUrl.Hostname()})
+	}
+	return net.DialTimeout("tcp", addr, 10*time.Second)
+}
+
+func (p *websocketAwareProxy) proxyWebsocket(writer http.ResponseWriter, request *http.Request) {
+	backendConn, err := p.dialBackend()
+	if err != nil {
+		http.Error(writer, "unable to reach backend", http.StatusBadGateway)
+		return
+	}
+	defer backendConn.Close()
+
+	hijacker, ok := writer.(http.Hijacker)
+	if !ok {
+		http.Error(writer, "websocket proxying not supported", http.StatusInternalServerError)
+		return
+	}
+	clientConn, _, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(writer, "unable to hijack connection", http.StatusInternalServerError)
+		return
+	}
+	defer clientConn.Close()
+
+	outbound := request.Clone(request.Context())
+	applyProxyRewrite(outbound, p.destUrl, p.opts)
+	outbound.RequestURI = ""
+	if err := outbound.Write(backendConn); err != nil {
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go copyAndSignal(backendConn, clientConn, done)
+	go copyAndSignal(clientConn, backendConn, done)
+	<-done
+}
+
+func copyAndSignal(dst io.Writer, src io.Reader, done chan<- struct{}) {
+	_, _ = io.Copy(dst, src)
+	done <- struct{}{}
+}
+
+var _ http.Handler = (*websocketAwareProxy)(nil)
@@ -0,0 +1,114 @@
+package webserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestBackendAddrDefaultsPort(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want string
+	}{
+		{"http://backend", "backend:80"},
+		{"https://backend", "backend:443"},
+		{"http://backend:9000", "backend:9000"},
+		{"ws://backend", "backend:80"},
+		{"wss://backend", "backend:443"},
+	}
+
+	for _, c := range cases {
+		u, err := url.Parse(c.raw)
+		if err != nil {
+			t.Fatalf("unable to parse %s: %v", c.raw, err)
+		}
+		if got := backendAddr(u); got != c.want {
+			t.Errorf("backendAddr(%s) = %s, want %s", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestIsTLSScheme(t *testing.T) {
+	cases := map[string]bool{
+		"http":  false,
+		"https": true,
+		"ws":    false,
+		"wss":   true,
+	}
+	for scheme, want := range cases {
+		if got := isTLSScheme(scheme); got != want {
+			t.Errorf("isTLSScheme(%s) = %v, want %v", scheme, got, want)
+		}
+	}
+}
+
+func TestTransportSchemeTranslatesWebsocketSchemes(t *testing.T) {
+	cases := map[string]string{
+		"ws":    "http",
+		"wss":   "https",
+		"http":  "http",
+		"https": "https",
+	}
+	for scheme, want := range cases {
+		if got := transportScheme(scheme); got != want {
+			t.Errorf("transportScheme(%s) = %s, want %s", scheme, got, want)
+		}
+	}
+}
+
+func TestApplyProxyRewriteStripsPrefixAndSetsForwardedHeaders(t *testing.T) {
+	destUrl, opts, err := parseProxyDest("http://backend?strip=/api")
+	if err != nil {
+		t.Fatalf("unable to parse proxy dest: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users", nil)
+	req.RemoteAddr = "203.0.113.1:12345"
+
+	applyProxyRewrite(req, destUrl, opts)
+
+	if req.URL.Path != "/users" {
+		t.Errorf("path = %s, want /users", req.URL.Path)
+	}
+	if req.Host != "backend" {
+		t.Errorf("host = %s, want backend", req.Host)
+	}
+	if got := req.Header.Get("X-Forwarded-For"); got != "203.0.113.1" {
+		t.Errorf("X-Forwarded-For = %s, want 203.0.113.1", got)
+	}
+	if req.Header.Get("X-Forwarded-Host") == "" {
+		t.Error("X-Forwarded-Host not set")
+	}
+}
+
+func TestApplyProxyRewritePreservesHost(t *testing.T) {
+	destUrl, opts, err := parseProxyDest("http://backend?preserveHost=true")
+	if err != nil {
+		t.Fatalf("unable to parse proxy dest: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Host = "original.example.com"
+
+	applyProxyRewrite(req, destUrl, opts)
+
+	if req.Host != "original.example.com" {
+		t.Errorf("host = %s, want original.example.com preserved", req.Host)
+	}
+}
+
+func TestApplyProxyRewriteTranslatesWebsocketSchemeForTransport(t *testing.T) {
+	destUrl, opts, err := parseProxyDest("ws://backend")
+	if err != nil {
+		t.Fatalf("unable to parse proxy dest: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	applyProxyRewrite(req, destUrl, opts)
+
+	if req.URL.Scheme != "http" {
+		t.Errorf("scheme = %s, want http (ws must be translated for http.Transport)", req.URL.Scheme)
+	}
+}
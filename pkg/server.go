@@ -5,15 +5,14 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"io"
-	"log"
+	"io/fs"
 	"mime"
 	"net/http"
-	"net/http/httputil"
-	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 type Server struct {
@@ -21,20 +20,18 @@ type Server struct {
 	logger    *logrus.Logger
 }
 
-var LoggingMiddleware mux.MiddlewareFunc = func(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("%s - %s\n", r.Method, r.RequestURI)
-		next.ServeHTTP(w, r)
-	})
-}
+func (s Server) Start(listenAddr string, opts ...StartOption) error {
+	cfg := &startConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
 
-func (s Server) Start(listenAddr string) error {
 	r := mux.NewRouter()
-	r.Use(LoggingMiddleware)
+	r.Use(s.LoggingMiddleware)
 	for _, e := range s.endpoints {
 		switch (e.Destination).(type) {
 		case NotFoundFileHandler:
-			r.NotFoundHandler = LoggingMiddleware(e.Destination)
+			r.NotFoundHandler = s.LoggingMiddleware(e.Destination)
 		default:
 		}
 	}
@@ -46,6 +43,11 @@ func (s Server) Start(listenAddr string) error {
 			fsHandler.notFoundHandler = r.NotFoundHandler
 			fmt.Printf("%s = %v\n", e.MountPoint, fsHandler)
 			r.NewRoute().PathPrefix(e.MountPoint).Handler(fsHandler)
+		case embedFSHandler:
+			efsHandler := e.Destination.(embedFSHandler)
+			efsHandler.notFoundHandler = r.NotFoundHandler
+			fmt.Printf("%s = %v\n", e.MountPoint, efsHandler)
+			r.NewRoute().PathPrefix(e.MountPoint).Handler(efsHandler)
 		case NotFoundFileHandler:
 		default:
 			fmt.Printf("%s = %v\n", e.MountPoint, e.Destination)
@@ -53,28 +55,17 @@ func (s Server) Start(listenAddr string) error {
 		}
 	}
 
-	return http.ListenAndServe(listenAddr, r)
-}
-
-func reverseProxy(dest string) (*httputil.ReverseProxy, error) {
-	destUrl, err := url.Parse(dest)
-	if err != nil {
-		return nil, fmt.Errorf("unable to parse URL: %v", err)
-	}
-
-	director := func(req *http.Request) {
-		req.URL.Scheme = destUrl.Scheme
-		req.URL.Host = destUrl.Host
-		req.URL.Path = destUrl.Path + req.URL.Path
-	}
-	proxy := &httputil.ReverseProxy{Director: director}
-	return proxy, nil
+	httpServer := &http.Server{Addr: listenAddr, Handler: r}
+	return serve(httpServer, cfg)
 }
 
-func New(endpoints []string) (*Server, error) {
+func New(endpoints []string, opts ...Option) (*Server, error) {
 	server := Server{endpoints: nil}
 	server.logger = logrus.New()
 	server.logger.SetLevel(logrus.DebugLevel)
+	for _, opt := range opts {
+		opt(&server)
+	}
 	for _, e := range endpoints {
 		parsedEndpoint, err := server.parseEndpoint(e)
 		if err != nil {
@@ -114,6 +105,12 @@ func (s *Server) parseDest(dest string) (http.Handler, error) {
 		return dest, nil
 	case "file":
 		return s.staticFiles(argument), nil
+	case "file+spa":
+		return s.staticFilesSPA(argument), nil
+	case "embed":
+		return s.newEmbedFSHandler(argument)
+	case "build":
+		return s.newBuildHandler(argument)
 	case "404":
 		return notFound(argument), nil
 	}
@@ -151,6 +148,12 @@ type filesystemHandler struct {
 	path            string
 	notFoundHandler http.Handler
 	logger          *logrus.Logger
+
+	// fallbackIndex, when set, is served with a 200 status (instead of
+	// delegating to notFoundHandler) for any request that would otherwise
+	// 404 and whose Accept header indicates an HTML navigation. This is
+	// what makes client-side routing (history API) work for SPA builds.
+	fallbackIndex string
 }
 
 func (s *Server) newFSHandler(path string) filesystemHandler {
@@ -165,6 +168,14 @@ func (s *Server) newFSHandler(path string) filesystemHandler {
 	}
 }
 
+// newSPAHandler is like newFSHandler but falls back to index.html for
+// unresolved HTML navigations, as required by client-side routers.
+func (s *Server) newSPAHandler(path string) filesystemHandler {
+	handler := s.newFSHandler(path)
+	handler.fallbackIndex = "index.html"
+	return handler
+}
+
 func (f filesystemHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
 	uri := request.RequestURI
 	if strings.HasPrefix(uri, "/") {
@@ -193,40 +204,152 @@ func (f filesystemHandler) ServeHTTP(writer http.ResponseWriter, request *http.R
 	stat, err := os.Stat(absFilePath)
 	if err != nil {
 		f.logger.Printf("unable to open file %s: %s", uri, err.Error())
-		if f.notFoundHandler == nil {
-			http.Error(writer, "404 - Not found (not found handler missing)", http.StatusNotFound)
-			return
-		}
-		f.notFoundHandler.ServeHTTP(writer, request)
+		f.serveNotFound(writer, request)
 		return
 	}
 
 	if stat.IsDir() {
 		absFilePath += "/index.html"
+		stat, err = os.Stat(absFilePath)
+		if err != nil {
+			f.logger.Printf("unable to open file %s: %s", uri, err.Error())
+			f.serveNotFound(writer, request)
+			return
+		}
 	}
 
 	file, err := os.Open(absFilePath)
 	if err != nil {
 		f.logger.Printf("unable to open file %s: %s", uri, err.Error())
-		if f.notFoundHandler == nil {
+		f.serveNotFound(writer, request)
+		return
+	}
+	defer file.Close()
+
+	etag, err := etagFor(absFilePath, stat.ModTime())
+	if err != nil {
+		f.logger.Printf("unable to compute etag for %s: %s", absFilePath, err.Error())
+	} else {
+		writer.Header().Set("ETag", etag)
+	}
+
+	contentType := mime.TypeByExtension("." + filepath.Ext(absFilePath))
+	writer.Header().Set("Content-Type", contentType)
+
+	// http.ServeContent honors If-None-Match/If-Modified-Since against the
+	// ETag/modtime set above and answers 304, range requests, and HEAD.
+	http.ServeContent(writer, request, absFilePath, stat.ModTime(), file)
+}
+
+// serveNotFound handles a miss below the mount point: an HTML navigation on
+// an SPA-mode handler gets the fallback index with a 200, everything else
+// falls through to notFoundHandler as before.
+func (f filesystemHandler) serveNotFound(writer http.ResponseWriter, request *http.Request) {
+	if f.fallbackIndex != "" && strings.Contains(request.Header.Get("Accept"), "text/html") {
+		if f.serveFallbackIndex(writer, request) {
+			return
+		}
+	}
+
+	if f.notFoundHandler == nil {
+		http.Error(writer, "404 - Not found (not found handler missing)", http.StatusNotFound)
+		return
+	}
+	f.notFoundHandler.ServeHTTP(writer, request)
+}
+
+func (f filesystemHandler) serveFallbackIndex(writer http.ResponseWriter, request *http.Request) bool {
+	indexPath := filepath.Join(f.path, f.fallbackIndex)
+	stat, err := os.Stat(indexPath)
+	if err != nil {
+		return false
+	}
+	file, err := os.Open(indexPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	if etag, err := etagFor(indexPath, stat.ModTime()); err == nil {
+		writer.Header().Set("ETag", etag)
+	}
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	http.ServeContent(writer, request, indexPath, stat.ModTime(), file)
+	return true
+}
+
+var _ http.Handler = filesystemHandler{}
+
+func (s *Server) staticFiles(path string) http.Handler {
+	return s.newFSHandler(path)
+}
+
+func (s *Server) staticFilesSPA(path string) http.Handler {
+	return s.newSPAHandler(path)
+}
+
+var registeredFS sync.Map // name string -> fs.FS
+
+// RegisterFS makes fsys available to the `embed=<name>` endpoint type under
+// the given name. Callers typically register an embed.FS populated via
+// //go:embed before calling webserver.New, e.g.:
+//
+//	//go:embed static/*
+//	var assets embed.FS
+//
+//	webserver.RegisterFS("assets", assets)
+func RegisterFS(name string, fsys fs.FS) {
+	registeredFS.Store(name, fsys)
+}
+
+type embedFSHandler struct {
+	name            string
+	fsys            fs.FS
+	notFoundHandler http.Handler
+	logger          *logrus.Logger
+}
+
+func (s *Server) newEmbedFSHandler(name string) (embedFSHandler, error) {
+	value, ok := registeredFS.Load(name)
+	if !ok {
+		return embedFSHandler{}, fmt.Errorf("no filesystem registered under name %q, call webserver.RegisterFS first", name)
+	}
+	return embedFSHandler{name: name, fsys: value.(fs.FS), logger: s.logger}, nil
+}
+
+func (e embedFSHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	uri := strings.TrimPrefix(request.RequestURI, "/")
+	if uri == "" {
+		uri = "."
+	}
+
+	file, err := e.fsys.Open(uri)
+	if err == nil {
+		if stat, statErr := file.Stat(); statErr == nil && stat.IsDir() {
+			_ = file.Close()
+			uri = path.Join(uri, "index.html")
+			file, err = e.fsys.Open(uri)
+		}
+	}
+	if err != nil {
+		e.logger.Printf("unable to open embedded file %s (fs %q): %s", uri, e.name, err.Error())
+		if e.notFoundHandler == nil {
 			http.Error(writer, "404 - Not found", http.StatusNotFound)
 			return
 		}
-		f.notFoundHandler.ServeHTTP(writer, request)
+		e.notFoundHandler.ServeHTTP(writer, request)
 		return
 	}
+	defer file.Close()
+
 	fileBytes, err := io.ReadAll(file)
 	if err != nil {
 		http.Error(writer, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	contentType := mime.TypeByExtension("." + filepath.Ext(absFilePath))
+	contentType := mime.TypeByExtension(path.Ext(uri))
 	writer.Header().Set("Content-Type", contentType)
 	_, _ = writer.Write(fileBytes)
 }
 
-var _ http.Handler = filesystemHandler{}
-
-func (s *Server) staticFiles(path string) http.Handler {
-	return s.newFSHandler(path)
-}
+var _ http.Handler = embedFSHandler{}
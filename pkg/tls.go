@@ -0,0 +1,105 @@
+package webserver
+
+import (
+	"net"
+	"net/http"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// StartOption configures the listener(s) used by Server.Start.
+type StartOption func(*startConfig)
+
+type startConfig struct {
+	certFile         string
+	keyFile          string
+	autocertDomains  []string
+	autocertCacheDir string
+	httpAddr         string
+}
+
+// WithTLS serves HTTPS using a static certificate/key pair.
+func WithTLS(certFile, keyFile string) StartOption {
+	return func(c *startConfig) {
+		c.certFile = certFile
+		c.keyFile = keyFile
+	}
+}
+
+// WithAutocert serves HTTPS using certificates obtained on demand from an
+// ACME CA (Let's Encrypt by default) for the given domains, cached under
+// cacheDir between restarts.
+func WithAutocert(domains []string, cacheDir string) StartOption {
+	return func(c *startConfig) {
+		c.autocertDomains = domains
+		c.autocertCacheDir = cacheDir
+	}
+}
+
+// WithHTTPRedirect additionally listens on httpAddr and redirects plain
+// HTTP requests to the HTTPS listener. It has no effect unless WithTLS or
+// WithAutocert is also given.
+func WithHTTPRedirect(httpAddr string) StartOption {
+	return func(c *startConfig) {
+		c.httpAddr = httpAddr
+	}
+}
+
+func (cfg *startConfig) tlsEnabled() bool {
+	return len(cfg.autocertDomains) > 0 || (cfg.certFile != "" && cfg.keyFile != "")
+}
+
+// serve starts server, enabling TLS (and HTTP/2 via the std library's
+// automatic h2 support over ListenAndServeTLS) when cfg asks for it, and
+// optionally runs a redirect-only HTTP listener alongside it.
+func serve(server *http.Server, cfg *startConfig) error {
+	if !cfg.tlsEnabled() {
+		return server.ListenAndServe()
+	}
+
+	if len(cfg.autocertDomains) > 0 {
+		cacheDir := cfg.autocertCacheDir
+		if cacheDir == "" {
+			cacheDir = "autocert-cache"
+		}
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.autocertDomains...),
+			Cache:      autocert.DirCache(cacheDir),
+		}
+		server.TLSConfig = manager.TLSConfig()
+
+		if cfg.httpAddr != "" {
+			go func() {
+				_ = http.ListenAndServe(cfg.httpAddr, manager.HTTPHandler(redirectToHTTPS(server.Addr)))
+			}()
+		}
+		return server.ListenAndServeTLS("", "")
+	}
+
+	if cfg.httpAddr != "" {
+		go func() {
+			_ = http.ListenAndServe(cfg.httpAddr, redirectToHTTPS(server.Addr))
+		}()
+	}
+	return server.ListenAndServeTLS(cfg.certFile, cfg.keyFile)
+}
+
+func redirectToHTTPS(httpsAddr string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		target := "https://" + host + httpsPortSuffix(httpsAddr) + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+	})
+}
+
+func httpsPortSuffix(listenAddr string) string {
+	_, port, err := net.SplitHostPort(listenAddr)
+	if err != nil || port == "" || port == "443" {
+		return ""
+	}
+	return ":" + port
+}